@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Gunes001/SimilarPictureCleaner/pkg/simpic"
+)
+
+// promptKeepIndex lists group on stdout, with a rendered thumbnail per image
+// where the terminal supports one, and asks the user which entry to keep,
+// defaulting to defaultIdx on a blank or invalid answer.
+func promptKeepIndex(group []simpic.Image, defaultIdx int) (int, error) {
+	for i, img := range group {
+		if thumb, err := renderThumbnail(img.Path); err == nil {
+			fmt.Print(thumb)
+		}
+
+		info, err := os.Stat(img.Path)
+		marker := ""
+		if i == defaultIdx {
+			marker = " (suggested)"
+		}
+		if err != nil {
+			fmt.Printf("  %d) %s [stat error: %v]%s\n", i+1, img.Path, err, marker)
+			continue
+		}
+		fmt.Printf("  %d) %s (%d bytes, modified %s)%s\n", i+1, img.Path, info.Size(), info.ModTime().Format("2006-01-02 15:04:05"), marker)
+	}
+
+	fmt.Printf("Keep which image? [1-%d, default %d]: ", len(group), defaultIdx+1)
+	reader := bufio.NewReader(os.Stdin)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return defaultIdx, nil
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return defaultIdx, nil
+	}
+	choice, err := strconv.Atoi(line)
+	if err != nil || choice < 1 || choice > len(group) {
+		fmt.Println("Invalid selection, keeping the suggested image.")
+		return defaultIdx, nil
+	}
+	return choice - 1, nil
+}
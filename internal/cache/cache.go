@@ -0,0 +1,156 @@
+// Package cache persists computed image hashes across runs so rescanning a
+// directory doesn't re-decode and re-hash files that haven't changed.
+package cache
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Config identifies the hashing configuration an Entry was computed under.
+// A cached hash is only reusable when the current run's Config matches.
+type Config struct {
+	Algo        string
+	Extended    bool
+	WorkingSize int
+	Filter      string
+}
+
+func (c Config) fingerprint() string {
+	return fmt.Sprintf("%s|ext=%v|size=%d|filter=%s", c.Algo, c.Extended, c.WorkingSize, c.Filter)
+}
+
+// Entry is one cached hash result.
+type Entry struct {
+	Config
+	Size        int64
+	ModTime     int64
+	ContentHash string
+	Hash        string
+	Width       int
+	Height      int
+}
+
+// Store is an on-disk cache keyed two ways: ByPath for the cheap path+size+
+// mtime check, and ByContent (keyed on a sha1 of the file's bytes plus the
+// hashing Config) for when a file has moved or its mtime changed but its
+// content hasn't. A Store is safe for concurrent use by multiple scanner
+// workers.
+type Store struct {
+	path      string
+	mu        sync.Mutex
+	dirty     bool
+	ByPath    map[string]Entry `json:"byPath"`
+	ByContent map[string]Entry `json:"byContent"`
+}
+
+// DefaultPath returns the cache file location under the user's cache
+// directory (honoring $XDG_CACHE_HOME on Linux via os.UserCacheDir).
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "simpiccleaner", "cache.json"), nil
+}
+
+func newStore(path string) *Store {
+	return &Store{path: path, ByPath: map[string]Entry{}, ByContent: map[string]Entry{}}
+}
+
+// New returns an empty Store that will be written to path on Save,
+// discarding whatever cache (if any) already exists there.
+func New(path string) *Store {
+	return newStore(path)
+}
+
+// Load reads the cache at path, returning an empty Store if it doesn't exist
+// yet or can't be parsed (a corrupt cache file is treated as a miss, not a
+// fatal error).
+func Load(path string) *Store {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return newStore(path)
+	}
+	var s Store
+	if err := json.Unmarshal(data, &s); err != nil {
+		return newStore(path)
+	}
+	s.path = path
+	if s.ByPath == nil {
+		s.ByPath = map[string]Entry{}
+	}
+	if s.ByContent == nil {
+		s.ByContent = map[string]Entry{}
+	}
+	return &s
+}
+
+// Save writes the cache back to disk if anything changed since it was
+// loaded.
+func (s *Store) Save() error {
+	if s == nil || !s.dirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o644)
+}
+
+// Lookup returns the cached entry for path if its size and mtime still
+// match and it was computed under cfg.
+func (s *Store) Lookup(path string, size, modTime int64, cfg Config) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.ByPath[path]
+	if !ok || e.Size != size || e.ModTime != modTime || e.Config != cfg {
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// LookupByContent returns the cached entry for a file whose contents hash to
+// contentHash under cfg, regardless of its path, size-on-disk metadata, or
+// mtime.
+func (s *Store) LookupByContent(contentHash string, cfg Config) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.ByContent[contentHash+"#"+cfg.fingerprint()]
+	return e, ok
+}
+
+// Put records e for path (and, for future renames/moves, for its content
+// hash too).
+func (s *Store) Put(path string, e Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ByPath[path] = e
+	s.ByContent[e.ContentHash+"#"+e.Config.fingerprint()] = e
+	s.dirty = true
+}
+
+// ContentHash returns the hex-encoded sha1 of path's contents.
+func ContentHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha1.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,16 @@
+package scanner
+
+// Blank-importing these packages registers their decoders with the standard
+// image package, so loadImage's single image.Decode call picks up every
+// format below instead of hand-rolling a decoder per extension. Animated
+// GIF/WebP decode to their first frame, since that's what their Decode
+// functions (as opposed to DecodeAll) return.
+import (
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
@@ -0,0 +1,15 @@
+//go:build avif
+
+package scanner
+
+// AVIF decoding pulls in a cgo binding to libavif, so it's opt-in via the
+// "avif" build tag rather than a default dependency. gen2brain/avif only
+// decodes AVIF, not HEIC/HEIF (a related but distinct container format), so
+// .heic/.heif are never added to knownImageExts.
+import (
+	_ "github.com/gen2brain/avif"
+)
+
+func init() {
+	knownImageExts[".avif"] = true
+}
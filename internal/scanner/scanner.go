@@ -0,0 +1,410 @@
+// Package scanner walks a directory, decodes each image it finds, and
+// computes a perceptual hash for it. It is kept independent of main so the
+// hashing pipeline can be reused outside of the CLI.
+package scanner
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/corona10/goimagehash"
+	"github.com/disintegration/imaging"
+
+	"github.com/Gunes001/SimilarPictureCleaner/internal/cache"
+)
+
+// DefaultWorkingSize is the long-edge size (in pixels) images are downsampled
+// to before hashing when Options.WorkingSize is left at zero.
+const DefaultWorkingSize = 512
+
+// trashDirName is skipped during the walk: it's where Deleter moves files it
+// removes, and this package has no way to know whether the Deleter that
+// created it used this scan's root, so it's excluded unconditionally.
+const trashDirName = ".trash"
+
+// ResampleFilter selects the resampling kernel used when downsampling an
+// image to its working size.
+type ResampleFilter string
+
+const (
+	FilterLanczos         ResampleFilter = "lanczos"
+	FilterCatmullRom      ResampleFilter = "catmullrom"
+	FilterLinear          ResampleFilter = "linear"
+	FilterBox             ResampleFilter = "box"
+	FilterNearestNeighbor ResampleFilter = "nearest"
+)
+
+func (f ResampleFilter) resolve() (imaging.ResampleFilter, error) {
+	switch f {
+	case FilterLanczos, "":
+		return imaging.Lanczos, nil
+	case FilterCatmullRom:
+		return imaging.CatmullRom, nil
+	case FilterLinear:
+		return imaging.Linear, nil
+	case FilterBox:
+		return imaging.Box, nil
+	case FilterNearestNeighbor:
+		return imaging.NearestNeighbor, nil
+	default:
+		return imaging.ResampleFilter{}, fmt.Errorf("unknown resample filter %q", f)
+	}
+}
+
+// knownImageExts are extensions we trust without sniffing the file. Anything
+// else (unknown or missing extension, or a format whose decoder isn't
+// registered in this build) falls back to a magic-byte check in isImageFile
+// so oddly-named or extension-less files aren't silently skipped. Formats
+// behind a build tag (e.g. .avif, see formats_avif.go) add themselves here
+// via init() only when that tag is set, rather than being trusted
+// unconditionally with no decoder to back them.
+var knownImageExts = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true,
+	".webp": true, ".bmp": true, ".tif": true, ".tiff": true,
+}
+
+// HashAlgo identifies which perceptual hash algorithm to use.
+type HashAlgo string
+
+const (
+	AlgoPHash HashAlgo = "phash"
+	AlgoDHash HashAlgo = "dhash"
+	AlgoAHash HashAlgo = "ahash"
+	AlgoWHash HashAlgo = "whash"
+)
+
+// Hash wraps the two hash types goimagehash produces (64-bit ImageHash and
+// 256-bit ExtImageHash) behind a single comparable value, so callers don't
+// need to know which algorithm or resolution produced it.
+type Hash struct {
+	imgHash *goimagehash.ImageHash
+	extHash *goimagehash.ExtImageHash
+}
+
+// Distance returns the Hamming distance between two hashes. Both hashes must
+// have come from the same algorithm/resolution.
+func (h Hash) Distance(other Hash) (int, error) {
+	if h.extHash != nil {
+		return h.extHash.Distance(other.extHash)
+	}
+	return h.imgHash.Distance(other.imgHash)
+}
+
+// Bits reports the bit length of the hash (64 for the standard algorithms,
+// 256 for the extended perception hash).
+func (h Hash) Bits() int {
+	if h.extHash != nil {
+		return 256
+	}
+	return 64
+}
+
+// String serializes h so it can be persisted (e.g. to the hash cache) and
+// later recovered with ParseHash.
+func (h Hash) String() string {
+	if h.extHash != nil {
+		return h.extHash.ToString()
+	}
+	return h.imgHash.ToString()
+}
+
+// ParseHash parses a hash previously produced by Hash.String. extended must
+// match how the original hash was computed.
+func ParseHash(s string, extended bool) (Hash, error) {
+	if extended {
+		h, err := goimagehash.ExtImageHashFromString(s)
+		if err != nil {
+			return Hash{}, err
+		}
+		return Hash{extHash: h}, nil
+	}
+	h, err := goimagehash.ImageHashFromString(s)
+	if err != nil {
+		return Hash{}, err
+	}
+	return Hash{imgHash: h}, nil
+}
+
+// ImageInfo is a scanned image and its computed hash.
+type ImageInfo struct {
+	Path string
+	Hash Hash
+}
+
+// Options configures a Scan.
+type Options struct {
+	Algo     HashAlgo
+	Extended bool
+	// Workers is the number of decode/hash goroutines to run. Zero means
+	// runtime.NumCPU().
+	Workers int
+	// WorkingSize is the long-edge size images are downsampled to before
+	// hashing. Zero means DefaultWorkingSize. Images already smaller than
+	// this are left alone.
+	WorkingSize int
+	// Filter is the resampling kernel used for that downsampling. Zero
+	// value means FilterLanczos.
+	Filter ResampleFilter
+	// NoCache disables the on-disk hash cache entirely.
+	NoCache bool
+	// RebuildCache discards any existing on-disk cache before scanning,
+	// forcing every file to be freshly decoded and hashed.
+	RebuildCache bool
+	// CachePath overrides where the on-disk hash cache is stored. Empty
+	// means cache.DefaultPath().
+	CachePath string
+}
+
+// Scan walks dir, decoding and hashing every supported image under a pool of
+// workers, and reports progress on stderr as it goes. A file that fails to
+// decode or hash is skipped rather than aborting the whole scan. The
+// directory walk streams paths to the workers rather than buffering the
+// whole tree up front, so memory use stays bounded regardless of directory
+// size.
+func Scan(dir string, opts Options) ([]ImageInfo, error) {
+	if !opts.Extended && opts.Algo == AlgoWHash {
+		return nil, fmt.Errorf("algo %q is not supported: goimagehash has no wavelet hash implementation", opts.Algo)
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workingSize := opts.WorkingSize
+	if workingSize <= 0 {
+		workingSize = DefaultWorkingSize
+	}
+	filter, err := opts.Filter.resolve()
+	if err != nil {
+		return nil, err
+	}
+	cfg := cache.Config{
+		Algo:        string(opts.Algo),
+		Extended:    opts.Extended,
+		WorkingSize: workingSize,
+		Filter:      string(opts.Filter),
+	}
+
+	var store *cache.Store
+	if !opts.NoCache {
+		cachePath := opts.CachePath
+		if cachePath == "" {
+			if cachePath, err = cache.DefaultPath(); err != nil {
+				fmt.Fprintln(os.Stderr, "hash cache disabled:", err)
+			}
+		}
+		if cachePath != "" {
+			if opts.RebuildCache {
+				store = cache.New(cachePath)
+			} else {
+				store = cache.Load(cachePath)
+			}
+		}
+	}
+
+	paths := make(chan string)
+	results := make(chan ImageInfo)
+	walkErr := make(chan error, 1)
+
+	go func() {
+		defer close(paths)
+		walkErr <- filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				// .trash holds files Deleter already moved out of the way;
+				// walking back into it would re-discover and re-group
+				// recovered duplicates on the next scan.
+				if info.Name() == trashDirName {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !isImageFile(path) {
+				return nil
+			}
+			paths <- path
+			return nil
+		})
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				img, err := scanFile(path, opts, workingSize, filter, cfg, store)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "\nskipping %s: %v\n", path, err)
+					continue
+				}
+				results <- img
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var images []ImageInfo
+	for img := range results {
+		images = append(images, img)
+		fmt.Fprintf(os.Stderr, "\rScanned %d images", len(images))
+	}
+	fmt.Fprintln(os.Stderr)
+
+	if err := store.Save(); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to save hash cache:", err)
+	}
+
+	if err := <-walkErr; err != nil {
+		return images, err
+	}
+	return images, nil
+}
+
+// scanFile produces the ImageInfo for path, consulting store first: a
+// path+size+mtime match skips everything, a content-hash match skips the
+// decode+hash but still updates the cheap key, and only a true miss pays for
+// decoding and hashing. store may be nil when caching is disabled.
+func scanFile(path string, opts Options, workingSize int, filter imaging.ResampleFilter, cfg cache.Config, store *cache.Store) (ImageInfo, error) {
+	info, statErr := os.Stat(path)
+	if statErr == nil && store != nil {
+		if entry, ok := store.Lookup(path, info.Size(), info.ModTime().UnixNano(), cfg); ok {
+			if hash, err := ParseHash(entry.Hash, opts.Extended); err == nil {
+				return ImageInfo{Path: path, Hash: hash}, nil
+			}
+		}
+	}
+
+	var contentHash string
+	if statErr == nil && store != nil {
+		if ch, err := cache.ContentHash(path); err == nil {
+			contentHash = ch
+			if entry, ok := store.LookupByContent(ch, cfg); ok {
+				if hash, err := ParseHash(entry.Hash, opts.Extended); err == nil {
+					store.Put(path, cache.Entry{
+						Config: cfg, Size: info.Size(), ModTime: info.ModTime().UnixNano(),
+						ContentHash: ch, Hash: entry.Hash, Width: entry.Width, Height: entry.Height,
+					})
+					return ImageInfo{Path: path, Hash: hash}, nil
+				}
+			}
+		}
+	}
+
+	img, original, err := loadImage(path, workingSize, filter)
+	if err != nil {
+		return ImageInfo{}, err
+	}
+	hash, err := computeHash(img, opts.Algo, opts.Extended)
+	if err != nil {
+		return ImageInfo{}, err
+	}
+
+	if statErr == nil && store != nil && contentHash != "" {
+		store.Put(path, cache.Entry{
+			Config: cfg, Size: info.Size(), ModTime: info.ModTime().UnixNano(),
+			ContentHash: contentHash, Hash: hash.String(), Width: original.Dx(), Height: original.Dy(),
+		})
+	}
+
+	return ImageInfo{Path: path, Hash: hash}, nil
+}
+
+// isImageFile reports whether path looks like an image. Known extensions are
+// trusted outright; anything else is sniffed by attempting to decode just
+// its header, so screenshots, scans, and downloads with an unusual or
+// missing extension still get picked up.
+func isImageFile(path string) bool {
+	if knownImageExts[strings.ToLower(filepath.Ext(path))] {
+		return true
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	_, _, err = image.DecodeConfig(file)
+	return err == nil
+}
+
+// loadImage decodes path, normalizes it to upright orientation using its
+// EXIF orientation tag (if any), and downsamples it to workingSize on its
+// long edge. Normalizing orientation and scale first means two copies of the
+// same photo that differ only by a re-saved EXIF tag or a resize/recompress
+// still hash the same. It also returns the image's bounds before
+// downsampling, since callers caching metadata about the file want its real
+// resolution, not the working size it was hashed at.
+func loadImage(path string, workingSize int, filter imaging.ResampleFilter) (image.Image, image.Rectangle, error) {
+	img, err := imaging.Open(path, imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, image.Rectangle{}, fmt.Errorf("decode %s: %w", path, err)
+	}
+	original := img.Bounds()
+	return downsample(img, workingSize, filter), original, nil
+}
+
+// downsample shrinks img so its long edge is at most size, preserving
+// aspect ratio. Images already at or below size are returned unchanged;
+// this stage never upscales.
+func downsample(img image.Image, size int, filter imaging.ResampleFilter) image.Image {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width <= size && height <= size {
+		return img
+	}
+	if width >= height {
+		return imaging.Resize(img, size, 0, filter)
+	}
+	return imaging.Resize(img, 0, size, filter)
+}
+
+// computeHash runs the requested algorithm against img. When extended is
+// true it always uses the 256-bit extended perception hash regardless of
+// algo, since goimagehash only offers an extended variant of PerceptionHash.
+func computeHash(img image.Image, algo HashAlgo, extended bool) (Hash, error) {
+	if extended {
+		h, err := goimagehash.ExtPerceptionHash(img, 16, 16)
+		if err != nil {
+			return Hash{}, err
+		}
+		return Hash{extHash: h}, nil
+	}
+
+	switch algo {
+	case AlgoPHash, "":
+		h, err := goimagehash.PerceptionHash(img)
+		if err != nil {
+			return Hash{}, err
+		}
+		return Hash{imgHash: h}, nil
+	case AlgoDHash:
+		h, err := goimagehash.DifferenceHash(img)
+		if err != nil {
+			return Hash{}, err
+		}
+		return Hash{imgHash: h}, nil
+	case AlgoAHash:
+		h, err := goimagehash.AverageHash(img)
+		if err != nil {
+			return Hash{}, err
+		}
+		return Hash{imgHash: h}, nil
+	case AlgoWHash:
+		return Hash{}, fmt.Errorf("algo %q is not supported: goimagehash has no wavelet hash implementation", algo)
+	default:
+		return Hash{}, fmt.Errorf("unknown hash algorithm %q", algo)
+	}
+}
@@ -3,30 +3,40 @@ package main
 import (
 	"flag"
 	"fmt"
-	"image"
-	"image/jpeg"
-	"image/png"
+	"net/http"
 	"os"
-	"path/filepath"
-	"sort"
-	"strings"
+	"runtime"
 
-	"github.com/corona10/goimagehash"
+	"github.com/Gunes001/SimilarPictureCleaner/pkg/simpic"
 )
 
-type ImageInfo struct {
-	Path string
-	Hash *goimagehash.ImageHash
-}
-
 func main() {
-	// Define the -d flag
-	deleteFlag := flag.Bool("d", false, "Specify whether to delete similar images")
+	deleteFlag := flag.Bool("d", false, "Actually delete/trash files instead of just reporting the plan")
+	algoFlag := flag.String("algo", string(simpic.AlgoPHash), "Hash algorithm to use: phash, dhash, ahash")
+	extFlag := flag.Bool("ext", false, "Use an extended 256-bit perception hash for finer resolution")
+	workersFlag := flag.Int("j", runtime.NumCPU(), "Number of parallel decode/hash workers")
+	workingSizeFlag := flag.Int("workingsize", simpic.DefaultWorkingSize, "Long-edge size (pixels) images are downsampled to before hashing")
+	filterFlag := flag.String("filter", string(simpic.FilterLanczos), "Resampling filter for downsampling: lanczos, catmullrom, linear, box, nearest")
+	keepFlag := flag.String("keep", string(simpic.KeepLargest), "Which file in a group to keep: largest, highest-quality, oldest, newest, shortest-path")
+	interactiveFlag := flag.Bool("interactive", false, "Prompt for which file to keep in each group instead of trusting -keep")
+	trashFlag := flag.Bool("trash", true, "Move deleted files into .trash/ under the scan root instead of removing them outright")
+	noCacheFlag := flag.Bool("no-cache", false, "Disable the on-disk hash cache")
+	rebuildCacheFlag := flag.Bool("rebuild-cache", false, "Discard the existing on-disk hash cache before scanning")
+	serveFlag := flag.String("serve", "", "Run an HTTP server on this address (e.g. :8080) instead of a one-shot scan")
 	flag.Parse()
 
-	// Check the number of arguments
+	if *serveFlag != "" {
+		svc := simpic.NewService()
+		fmt.Println("Listening on", *serveFlag)
+		if err := http.ListenAndServe(*serveFlag, simpic.NewHTTPHandler(svc)); err != nil {
+			fmt.Println("Server error:", err)
+		}
+		return
+	}
+
 	if len(flag.Args()) < 2 {
-		fmt.Println("Usage: go run main.go [-d] <directory> <similarity percentage>")
+		fmt.Println("Usage: go run . [-d] [-algo phash|dhash|ahash] [-ext] [-j N] [-workingsize N] [-filter NAME] [-keep POLICY] [-interactive] [-trash=false] [-no-cache] [-rebuild-cache] <directory> <similarity percentage>")
+		fmt.Println("   or: go run . -serve :8080")
 		return
 	}
 
@@ -37,37 +47,76 @@ func main() {
 		return
 	}
 
-	images, err := loadImages(dir)
+	images, err := simpic.DefaultScanner{}.Scan(dir, simpic.ScanOptions{
+		Algo:         simpic.HashAlgo(*algoFlag),
+		Extended:     *extFlag,
+		Workers:      *workersFlag,
+		WorkingSize:  *workingSizeFlag,
+		Filter:       simpic.ResampleFilter(*filterFlag),
+		NoCache:      *noCacheFlag,
+		RebuildCache: *rebuildCacheFlag,
+	})
 	if err != nil {
 		fmt.Println("Error loading images:", err)
 		return
 	}
 
-	groups := findSimilarImages(images, similarityThreshold)
+	groups := simpic.DefaultGrouper{}.Group(images, similarityThreshold)
 
-	// Display similar images
+	trashRoot := ""
+	if *trashFlag {
+		trashRoot = dir
+	}
+
+	deleter := simpic.DefaultDeleter{}
+	var totalSaved int64
 	for _, group := range groups {
+		keepIdx, err := deleter.SelectKeep(group, simpic.KeepPolicy(*keepFlag))
+		if err != nil {
+			fmt.Println("Error selecting keep policy:", err)
+			continue
+		}
+		if *interactiveFlag {
+			keepIdx, err = promptKeepIndex(group, keepIdx)
+			if err != nil {
+				fmt.Println("Error reading selection:", err)
+				continue
+			}
+		}
+
 		fmt.Println("Similar images:")
-		for _, img := range group {
-			fmt.Println(img.Path)
+		for i, img := range group {
+			action := "delete"
+			if i == keepIdx {
+				action = "keep"
+			}
+			fmt.Printf("  [%s] %s\n", action, img.Path)
 		}
-		fmt.Println()
-	}
 
-	// Delete similar images if the -d flag is set
-	if *deleteFlag {
-		var totalSaved int64
-		for _, group := range groups {
-			if len(group) > 1 {
-				saved, err := deleteSimilarImages(group)
-				if err != nil {
-					fmt.Println("Error deleting images:", err)
+		if *deleteFlag {
+			saved, err := deleter.Delete(group, keepIdx, trashRoot)
+			if err != nil {
+				fmt.Println("Error deleting images:", err)
+				continue
+			}
+			totalSaved += saved
+		} else {
+			for i, img := range group {
+				if i == keepIdx {
 					continue
 				}
-				totalSaved += saved
+				if info, err := os.Stat(img.Path); err == nil {
+					totalSaved += info.Size()
+				}
 			}
 		}
+		fmt.Println()
+	}
+
+	if *deleteFlag {
 		fmt.Printf("Total space saved: %d bytes\n", totalSaved)
+	} else {
+		fmt.Printf("Dry run: would free %d bytes. Re-run with -d to apply.\n", totalSaved)
 	}
 }
 
@@ -82,102 +131,3 @@ func parsePercentage(percentageStr string) (float64, error) {
 	}
 	return percentage / 100, nil
 }
-
-func loadImages(dir string) ([]ImageInfo, error) {
-	var images []ImageInfo
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() && (strings.HasSuffix(strings.ToLower(path), ".jpg") || strings.HasSuffix(strings.ToLower(path), ".jpeg") || strings.HasSuffix(strings.ToLower(path), ".png")) {
-			img, err := loadImage(path)
-			if err != nil {
-				return err
-			}
-			hash, err := goimagehash.PerceptionHash(img)
-			if err != nil {
-				return err
-			}
-			images = append(images, ImageInfo{Path: path, Hash: hash})
-		}
-		return nil
-	})
-	return images, err
-}
-
-func loadImage(path string) (image.Image, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	if strings.HasSuffix(strings.ToLower(path), ".jpg") || strings.HasSuffix(strings.ToLower(path), ".jpeg") {
-		return jpeg.Decode(file)
-	} else if strings.HasSuffix(strings.ToLower(path), ".png") {
-		return png.Decode(file)
-	}
-	return nil, fmt.Errorf("unsupported image format")
-}
-
-func findSimilarImages(images []ImageInfo, similarityThreshold float64) [][]ImageInfo {
-	var groups [][]ImageInfo
-	used := make(map[int]bool)
-
-	for i := 0; i < len(images); i++ {
-		if used[i] {
-			continue
-		}
-		group := []ImageInfo{images[i]}
-		for j := i + 1; j < len(images); j++ {
-			if used[j] {
-				continue
-			}
-			distance, err := images[i].Hash.Distance(images[j].Hash)
-			if err != nil {
-				fmt.Println("Error calculating distance:", err)
-				continue
-			}
-			similarity := 1 - float64(distance)/64.0
-			if similarity >= similarityThreshold {
-				group = append(group, images[j])
-				used[j] = true
-			}
-		}
-		if len(group) > 1 {
-			groups = append(groups, group)
-		}
-	}
-
-	return groups
-}
-
-func deleteSimilarImages(group []ImageInfo) (int64, error) {
-	sort.Slice(group, func(i, j int) bool {
-		distanceI, err := group[i].Hash.Distance(group[0].Hash)
-		if err != nil {
-			fmt.Println("Error calculating distance:", err)
-			return false
-		}
-		distanceJ, err := group[j].Hash.Distance(group[0].Hash)
-		if err != nil {
-			fmt.Println("Error calculating distance:", err)
-			return false
-		}
-		return distanceI < distanceJ
-	})
-
-	var totalSaved int64
-	for i := 1; i < len(group); i++ {
-		info, err := os.Stat(group[i].Path)
-		if err != nil {
-			return 0, err
-		}
-		totalSaved += info.Size()
-		err = os.Remove(group[i].Path)
-		if err != nil {
-			return 0, err
-		}
-	}
-	return totalSaved, nil
-}
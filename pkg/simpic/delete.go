@@ -0,0 +1,156 @@
+package simpic
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// KeepPolicy decides which image in a group of similar images survives.
+type KeepPolicy string
+
+const (
+	KeepLargest        KeepPolicy = "largest"
+	KeepHighestQuality KeepPolicy = "highest-quality"
+	KeepOldest         KeepPolicy = "oldest"
+	KeepNewest         KeepPolicy = "newest"
+	KeepShortestPath   KeepPolicy = "shortest-path"
+)
+
+// Deleter selects which image in a group survives and removes the rest.
+type Deleter interface {
+	SelectKeep(group []Image, policy KeepPolicy) (int, error)
+	Delete(group []Image, keepIdx int, trashRoot string) (int64, error)
+}
+
+// DefaultDeleter evaluates KeepPolicy against the filesystem (size,
+// resolution, mtime, path length) and removes files directly or via a
+// .trash directory.
+type DefaultDeleter struct{}
+
+// SelectKeep applies policy to group and returns the index of the image
+// that should survive. Images the policy can't evaluate (e.g. a file that
+// has since disappeared) are skipped rather than failing the whole group.
+func (DefaultDeleter) SelectKeep(group []Image, policy KeepPolicy) (int, error) {
+	switch policy {
+	case KeepLargest, "":
+		return bestIndex(group, resolutionOf, true)
+	case KeepHighestQuality:
+		return bestIndex(group, fileSizeOf, true)
+	case KeepOldest:
+		return bestIndex(group, mtimeOf, false)
+	case KeepNewest:
+		return bestIndex(group, mtimeOf, true)
+	case KeepShortestPath:
+		return bestIndex(group, pathLenOf, false)
+	default:
+		return 0, fmt.Errorf("unknown keep policy %q", policy)
+	}
+}
+
+// Delete removes every image in group except the one at keepIdx, returning
+// the number of bytes freed. When trashRoot is non-empty, removed files are
+// moved under it instead of being deleted outright, so a bad keep-policy
+// choice can still be undone.
+func (DefaultDeleter) Delete(group []Image, keepIdx int, trashRoot string) (int64, error) {
+	var totalSaved int64
+	for i, img := range group {
+		if i == keepIdx {
+			continue
+		}
+		info, err := os.Stat(img.Path)
+		if err != nil {
+			return totalSaved, err
+		}
+		totalSaved += info.Size()
+		if trashRoot != "" {
+			if err := moveToTrash(trashRoot, img.Path); err != nil {
+				return totalSaved, err
+			}
+			continue
+		}
+		if err := os.Remove(img.Path); err != nil {
+			return totalSaved, err
+		}
+	}
+	return totalSaved, nil
+}
+
+func bestIndex(group []Image, metric func(Image) (int64, error), preferHighest bool) (int, error) {
+	best := -1
+	var bestVal int64
+	for i, img := range group {
+		v, err := metric(img)
+		if err != nil {
+			fmt.Println("Error evaluating keep policy:", err)
+			continue
+		}
+		if best == -1 || (preferHighest && v > bestVal) || (!preferHighest && v < bestVal) {
+			best, bestVal = i, v
+		}
+	}
+	if best == -1 {
+		return 0, fmt.Errorf("could not evaluate keep policy for any image in group")
+	}
+	return best, nil
+}
+
+func resolutionOf(img Image) (int64, error) {
+	f, err := os.Open(img.Path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, err
+	}
+	return int64(cfg.Width) * int64(cfg.Height), nil
+}
+
+func fileSizeOf(img Image) (int64, error) {
+	info, err := os.Stat(img.Path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func mtimeOf(img Image) (int64, error) {
+	info, err := os.Stat(img.Path)
+	if err != nil {
+		return 0, err
+	}
+	return info.ModTime().UnixNano(), nil
+}
+
+func pathLenOf(img Image) (int64, error) {
+	return int64(len(img.Path)), nil
+}
+
+// moveToTrash relocates path into a ".trash" directory under root, renaming
+// on collision rather than overwriting whatever is already there.
+func moveToTrash(root, path string) error {
+	trashDir := filepath.Join(root, ".trash")
+	if err := os.MkdirAll(trashDir, 0o755); err != nil {
+		return err
+	}
+	dest := uniquePath(filepath.Join(trashDir, filepath.Base(path)))
+	return os.Rename(path, dest)
+}
+
+func uniquePath(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
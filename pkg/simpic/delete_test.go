@@ -0,0 +1,99 @@
+package simpic
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFileOfSize(t *testing.T, path string, n int) {
+	t.Helper()
+	if err := os.WriteFile(path, make([]byte, n), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSelectKeepLargestPrefersHighestResolution(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "small.png")
+	big := filepath.Join(dir, "big.png")
+	writeTestPNG(t, small, 8, 8, color.Gray{Y: 0}, false)
+	writeTestPNG(t, big, 64, 64, color.Gray{Y: 0}, false)
+
+	group := []Image{{Path: small}, {Path: big}}
+	idx, err := DefaultDeleter{}.SelectKeep(group, KeepLargest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group[idx].Path != big {
+		t.Errorf("KeepLargest should keep the higher-resolution image, kept %s", group[idx].Path)
+	}
+}
+
+func TestSelectKeepHighestQualityPrefersBiggestFile(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "small.bin")
+	big := filepath.Join(dir, "big.bin")
+	writeFileOfSize(t, small, 10)
+	writeFileOfSize(t, big, 1000)
+
+	group := []Image{{Path: small}, {Path: big}}
+	idx, err := DefaultDeleter{}.SelectKeep(group, KeepHighestQuality)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group[idx].Path != big {
+		t.Errorf("KeepHighestQuality should keep the larger file, kept %s", group[idx].Path)
+	}
+}
+
+func TestSelectKeepOldestAndNewest(t *testing.T) {
+	dir := t.TempDir()
+	older := filepath.Join(dir, "older.bin")
+	newer := filepath.Join(dir, "newer.bin")
+	writeFileOfSize(t, older, 1)
+	writeFileOfSize(t, newer, 1)
+
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(older, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	group := []Image{{Path: older}, {Path: newer}}
+
+	idx, err := DefaultDeleter{}.SelectKeep(group, KeepOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group[idx].Path != older {
+		t.Errorf("KeepOldest should keep %s, kept %s", older, group[idx].Path)
+	}
+
+	idx, err = DefaultDeleter{}.SelectKeep(group, KeepNewest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group[idx].Path != newer {
+		t.Errorf("KeepNewest should keep %s, kept %s", newer, group[idx].Path)
+	}
+}
+
+func TestSelectKeepShortestPath(t *testing.T) {
+	group := []Image{{Path: "a/very/long/nested/path.jpg"}, {Path: "b.jpg"}}
+	idx, err := DefaultDeleter{}.SelectKeep(group, KeepShortestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group[idx].Path != "b.jpg" {
+		t.Errorf("KeepShortestPath should keep the shorter path, kept %s", group[idx].Path)
+	}
+}
+
+func TestSelectKeepUnknownPolicy(t *testing.T) {
+	group := []Image{{Path: "a.jpg"}}
+	if _, err := (DefaultDeleter{}).SelectKeep(group, KeepPolicy("bogus")); err == nil {
+		t.Error("expected an error for an unknown keep policy")
+	}
+}
@@ -0,0 +1,127 @@
+package simpic
+
+import "fmt"
+
+// DefaultGrouper groups images using a BK-tree indexed on Hamming distance,
+// so grouping is sub-quadratic even for large image sets: instead of
+// comparing every pair, each unused image queries the tree for its
+// neighbors within the distance budget implied by the threshold.
+type DefaultGrouper struct{}
+
+func (DefaultGrouper) Group(images []Image, similarityThreshold float64) [][]Image {
+	if len(images) == 0 {
+		return nil
+	}
+
+	tree := NewBKTree()
+	byPath := make(map[string]Image, len(images))
+	for _, img := range images {
+		tree.Insert(img)
+		byPath[img.Path] = img
+	}
+
+	maxDistance := int((1 - similarityThreshold) * float64(images[0].Hash.Bits()))
+	used := make(map[string]bool)
+	var groups [][]Image
+
+	for _, img := range images {
+		if used[img.Path] {
+			continue
+		}
+		neighbors := tree.Search(img.Hash, maxDistance)
+		var group []Image
+		for _, path := range neighbors {
+			if used[path] {
+				continue
+			}
+			used[path] = true
+			group = append(group, byPath[path])
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
+// bkNode is a single node in a BK-tree: it holds one indexed image and the
+// subtrees reachable from it, keyed by the Hamming distance from this node's
+// hash to the child's hash.
+type bkNode struct {
+	image    Image
+	children map[int]*bkNode
+}
+
+// BKTree indexes image hashes by Hamming distance so that finding all images
+// within a distance threshold of a query doesn't require comparing against
+// every indexed image, unlike the naive O(n^2) all-pairs scan. It's exported
+// so it can be built and queried directly, independent of Grouper.
+type BKTree struct {
+	root *bkNode
+}
+
+// NewBKTree returns an empty BKTree.
+func NewBKTree() *BKTree {
+	return &BKTree{}
+}
+
+// Insert adds img to the tree. It walks down from the root following the
+// edge equal to the distance between the current node and img, creating a
+// new child when no edge for that distance exists yet.
+func (t *BKTree) Insert(img Image) {
+	node := &bkNode{image: img}
+	if t.root == nil {
+		t.root = node
+		return
+	}
+
+	cur := t.root
+	for {
+		d, err := cur.image.Hash.Distance(img.Hash)
+		if err != nil {
+			fmt.Println("Error calculating distance:", err)
+			return
+		}
+		child, ok := cur.children[d]
+		if !ok {
+			if cur.children == nil {
+				cur.children = make(map[int]*bkNode)
+			}
+			cur.children[d] = node
+			return
+		}
+		cur = child
+	}
+}
+
+// Search returns the paths of all indexed images within threshold of query,
+// including query itself if it is indexed. It visits a node's children only
+// along edges that could still lead to a match, pruning the rest of the
+// subtree via the triangle inequality.
+func (t *BKTree) Search(query Hash, threshold int) []string {
+	if t.root == nil {
+		return nil
+	}
+
+	var results []string
+	var walk func(n *bkNode)
+	walk = func(n *bkNode) {
+		d, err := n.image.Hash.Distance(query)
+		if err != nil {
+			fmt.Println("Error calculating distance:", err)
+			return
+		}
+		if d <= threshold {
+			results = append(results, n.image.Path)
+		}
+		for edge, child := range n.children {
+			if edge >= d-threshold && edge <= d+threshold {
+				walk(child)
+			}
+		}
+	}
+	walk(t.root)
+
+	return results
+}
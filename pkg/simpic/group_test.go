@@ -0,0 +1,175 @@
+package simpic
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestPNG writes a w x h PNG at path filled with fill, except that the
+// right half of the image is inverted when split is true, giving the
+// perceptual hash something to key off of besides a flat average.
+func writeTestPNG(t *testing.T, path string, w, h int, fill color.Gray, split bool) {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := fill
+			if split && x >= w/2 {
+				v = color.Gray{Y: 255 - fill.Y}
+			}
+			img.SetGray(x, y, v)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// scanTestImages writes each named fixture under a temp directory and
+// returns the real, hashed Images DefaultScanner produces for them, so tests
+// exercise BKTree and Group against genuine hashes instead of guessed ones.
+func scanTestImages(t *testing.T, fixtures map[string]func(path string)) []Image {
+	t.Helper()
+	dir := t.TempDir()
+	for name, write := range fixtures {
+		write(filepath.Join(dir, name))
+	}
+	images, err := DefaultScanner{}.Scan(dir, ScanOptions{Algo: AlgoAHash, Workers: 1, NoCache: true})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	return images
+}
+
+func imageNamed(t *testing.T, images []Image, name string) Image {
+	t.Helper()
+	for _, img := range images {
+		if filepath.Base(img.Path) == name {
+			return img
+		}
+	}
+	t.Fatalf("no scanned image named %q", name)
+	return Image{}
+}
+
+// nearDuplicates returns three scanned images where b is a near-duplicate of
+// a (same split pattern, boundary nudged a few columns) and c is clearly
+// different (the pattern inverted), so distance(a, b) < distance(a, c).
+func nearDuplicates(t *testing.T) (a, b, c Image) {
+	t.Helper()
+	images := scanTestImages(t, map[string]func(string){
+		"a.png": func(p string) { writeTestPNG(t, p, 32, 32, color.Gray{Y: 0}, true) },
+		"b.png": func(p string) {
+			img := image.NewGray(image.Rect(0, 0, 32, 32))
+			for y := 0; y < 32; y++ {
+				for x := 0; x < 32; x++ {
+					v := color.Gray{Y: 0}
+					if x >= 14 {
+						v = color.Gray{Y: 255}
+					}
+					img.SetGray(x, y, v)
+				}
+			}
+			f, err := os.Create(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			if err := png.Encode(f, img); err != nil {
+				t.Fatal(err)
+			}
+		},
+		"c.png": func(p string) { writeTestPNG(t, p, 32, 32, color.Gray{Y: 255}, true) },
+	})
+	return imageNamed(t, images, "a.png"), imageNamed(t, images, "b.png"), imageNamed(t, images, "c.png")
+}
+
+func TestBKTreeSearchFindsCloserImageAndPrunesFarOne(t *testing.T) {
+	a, b, c := nearDuplicates(t)
+
+	dab, err := a.Hash.Distance(b.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dac, err := a.Hash.Distance(c.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dab >= dac {
+		t.Fatalf("test fixtures aren't distinct enough: distance(a,b)=%d, distance(a,c)=%d", dab, dac)
+	}
+
+	tree := NewBKTree()
+	tree.Insert(a)
+	tree.Insert(b)
+	tree.Insert(c)
+
+	found := map[string]bool{}
+	for _, p := range tree.Search(a.Hash, dab) {
+		found[filepath.Base(p)] = true
+	}
+	if !found["a.png"] {
+		t.Error("Search should include the query image itself at distance 0")
+	}
+	if !found["b.png"] {
+		t.Error("Search should include b.png, which is within the threshold")
+	}
+	if found["c.png"] {
+		t.Error("Search should not include c.png, which is further than the threshold")
+	}
+}
+
+func TestBKTreeSearchOnEmptyTree(t *testing.T) {
+	tree := NewBKTree()
+	if got := tree.Search(Hash{}, 10); got != nil {
+		t.Errorf("Search on an empty tree should return nil, got %v", got)
+	}
+}
+
+func TestGroupUsesSimilarityThresholdAsADistanceBudget(t *testing.T) {
+	a, b, c := nearDuplicates(t)
+
+	dab, err := a.Hash.Distance(b.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dac, err := a.Hash.Distance(c.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dab >= dac {
+		t.Fatalf("test fixtures aren't distinct enough: distance(a,b)=%d, distance(a,c)=%d", dab, dac)
+	}
+
+	// Pick a threshold whose rounded maxDistance lands exactly on dab, so a
+	// and b fall within budget but c doesn't.
+	bits := float64(a.Hash.Bits())
+	threshold := 1 - float64(dab)/bits
+
+	groups := (DefaultGrouper{}).Group([]Image{a, b, c}, threshold)
+	if len(groups) != 1 {
+		t.Fatalf("want 1 group, got %d: %v", len(groups), groups)
+	}
+	if len(groups[0]) != 2 {
+		t.Fatalf("want a 2-image group, got %d", len(groups[0]))
+	}
+	names := map[string]bool{filepath.Base(groups[0][0].Path): true, filepath.Base(groups[0][1].Path): true}
+	if !names["a.png"] || !names["b.png"] {
+		t.Errorf("want a.png and b.png grouped together, got %v", names)
+	}
+}
+
+func TestGroupReturnsNilForNoImages(t *testing.T) {
+	if got := (DefaultGrouper{}).Group(nil, 0.9); got != nil {
+		t.Errorf("Group(nil, ...) should return nil, got %v", got)
+	}
+}
@@ -0,0 +1,121 @@
+package simpic
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// scanRequest is the POST /scan body.
+type scanRequest struct {
+	Dir                 string  `json:"dir"`
+	SimilarityThreshold float64 `json:"similarityThreshold"`
+	Algo                string  `json:"algo"`
+	Extended            bool    `json:"extended"`
+	Workers             int     `json:"workers"`
+	WorkingSize         int     `json:"workingSize"`
+	Filter              string  `json:"filter"`
+	NoCache             bool    `json:"noCache"`
+	RebuildCache        bool    `json:"rebuildCache"`
+}
+
+type scanResponse struct {
+	Groups int `json:"groups"`
+	Images int `json:"images"`
+}
+
+// NewHTTPHandler exposes svc over HTTP: POST /scan runs a scan, GET /groups
+// lists the groups it found, and DELETE /groups/{id}/duplicates removes the
+// duplicates from one group.
+func NewHTTPHandler(svc *Service) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/scan", func(w http.ResponseWriter, r *http.Request) {
+		handleScan(w, r, svc)
+	})
+	mux.HandleFunc("/groups", func(w http.ResponseWriter, r *http.Request) {
+		handleGroups(w, r, svc)
+	})
+	mux.HandleFunc("/groups/", func(w http.ResponseWriter, r *http.Request) {
+		handleGroupDuplicates(w, r, svc)
+	})
+	return mux
+}
+
+func handleScan(w http.ResponseWriter, r *http.Request, svc *Service) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req scanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Dir == "" {
+		http.Error(w, "dir is required", http.StatusBadRequest)
+		return
+	}
+
+	groups, err := svc.Scan(req.Dir, req.SimilarityThreshold, ScanOptions{
+		Algo:         HashAlgo(req.Algo),
+		Extended:     req.Extended,
+		Workers:      req.Workers,
+		WorkingSize:  req.WorkingSize,
+		Filter:       ResampleFilter(req.Filter),
+		NoCache:      req.NoCache,
+		RebuildCache: req.RebuildCache,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	images := 0
+	for _, g := range groups {
+		images += len(g.Images)
+	}
+	writeJSON(w, http.StatusOK, scanResponse{Groups: len(groups), Images: images})
+}
+
+func handleGroups(w http.ResponseWriter, r *http.Request, svc *Service) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, svc.Groups())
+}
+
+type deleteDuplicatesResponse struct {
+	FreedBytes int64 `json:"freedBytes"`
+}
+
+func handleGroupDuplicates(w http.ResponseWriter, r *http.Request, svc *Service) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/groups/")
+	id, rest, ok := strings.Cut(path, "/")
+	if !ok || rest != "duplicates" || id == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	policy := KeepPolicy(r.URL.Query().Get("keep"))
+	trash := r.URL.Query().Get("trash") != "false"
+
+	freed, err := svc.DeleteDuplicates(id, policy, trash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, deleteDuplicatesResponse{FreedBytes: freed})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,92 @@
+package simpic
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Group is a set of mutually similar images, identified by an ID stable for
+// the lifetime of the Service that produced it.
+type Group struct {
+	ID     string  `json:"id"`
+	Images []Image `json:"images"`
+}
+
+// Service holds the result of the most recent scan and lets callers act on
+// it by group ID. It's the shared core behind both the CLI and the HTTP
+// server: neither talks to Scanner/Grouper/Deleter directly.
+type Service struct {
+	scanner Scanner
+	grouper Grouper
+	deleter Deleter
+
+	mu     sync.Mutex
+	root   string
+	groups []Group
+}
+
+// NewService returns a Service wired to the default scanner, grouper, and
+// deleter.
+func NewService() *Service {
+	return &Service{scanner: DefaultScanner{}, grouper: DefaultGrouper{}, deleter: DefaultDeleter{}}
+}
+
+// Scan walks dir, groups the results at similarityThreshold, and replaces
+// the Service's current groups with them.
+func (s *Service) Scan(dir string, similarityThreshold float64, opts ScanOptions) ([]Group, error) {
+	images, err := s.scanner.Scan(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	raw := s.grouper.Group(images, similarityThreshold)
+
+	groups := make([]Group, len(raw))
+	for i, g := range raw {
+		groups[i] = Group{ID: fmt.Sprintf("g%d", i), Images: g}
+	}
+
+	s.mu.Lock()
+	s.root = dir
+	s.groups = groups
+	s.mu.Unlock()
+
+	return groups, nil
+}
+
+// Groups returns the groups from the most recent Scan.
+func (s *Service) Groups() []Group {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Group(nil), s.groups...)
+}
+
+// DeleteDuplicates applies policy to the group identified by id and removes
+// everything but the surviving image, returning the bytes freed. When trash
+// is true, removed files are moved to .trash under the scanned root instead
+// of being deleted outright.
+func (s *Service) DeleteDuplicates(id string, policy KeepPolicy, trash bool) (int64, error) {
+	s.mu.Lock()
+	root := s.root
+	var group []Image
+	for _, g := range s.groups {
+		if g.ID == id {
+			group = g.Images
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if group == nil {
+		return 0, fmt.Errorf("group %q not found", id)
+	}
+
+	keepIdx, err := s.deleter.SelectKeep(group, policy)
+	if err != nil {
+		return 0, err
+	}
+	trashRoot := ""
+	if trash {
+		trashRoot = root
+	}
+	return s.deleter.Delete(group, keepIdx, trashRoot)
+}
@@ -0,0 +1,76 @@
+// Package simpic is the importable core of the cleaner: scanning a
+// directory, hashing what it finds, grouping similar images, and deleting
+// the duplicates out of a group. The CLI and the HTTP server in this module
+// are both thin wrappers around it.
+package simpic
+
+import (
+	"github.com/Gunes001/SimilarPictureCleaner/internal/scanner"
+)
+
+// Image is a scanned file and its perceptual hash.
+type Image = scanner.ImageInfo
+
+// Hash is a perceptual hash produced by a Scanner.
+type Hash = scanner.Hash
+
+// HashAlgo identifies which perceptual hash algorithm to use.
+type HashAlgo = scanner.HashAlgo
+
+// ResampleFilter selects the resampling kernel used when downsampling an
+// image to its working size.
+type ResampleFilter = scanner.ResampleFilter
+
+const (
+	AlgoPHash = scanner.AlgoPHash
+	AlgoDHash = scanner.AlgoDHash
+	AlgoAHash = scanner.AlgoAHash
+	AlgoWHash = scanner.AlgoWHash
+)
+
+const (
+	FilterLanczos         = scanner.FilterLanczos
+	FilterCatmullRom      = scanner.FilterCatmullRom
+	FilterLinear          = scanner.FilterLinear
+	FilterBox             = scanner.FilterBox
+	FilterNearestNeighbor = scanner.FilterNearestNeighbor
+)
+
+// DefaultWorkingSize is the long-edge size (in pixels) images are
+// downsampled to before hashing when ScanOptions.WorkingSize is left at
+// zero.
+const DefaultWorkingSize = scanner.DefaultWorkingSize
+
+// ScanOptions configures a Scanner.Scan call.
+type ScanOptions = scanner.Options
+
+// Scanner walks a directory and returns the images found in it along with
+// their perceptual hashes.
+type Scanner interface {
+	Scan(dir string, opts ScanOptions) ([]Image, error)
+}
+
+// DefaultScanner is the Scanner backed by internal/scanner's worker-pool
+// walk.
+type DefaultScanner struct{}
+
+func (DefaultScanner) Scan(dir string, opts ScanOptions) ([]Image, error) {
+	return scanner.Scan(dir, opts)
+}
+
+// Hasher compares two already-computed image hashes.
+type Hasher interface {
+	Distance(a, b Image) (int, error)
+}
+
+// DefaultHasher compares hashes via Image.Hash.Distance.
+type DefaultHasher struct{}
+
+func (DefaultHasher) Distance(a, b Image) (int, error) {
+	return a.Hash.Distance(b.Hash)
+}
+
+// Grouper partitions a set of images into groups of mutual near-duplicates.
+type Grouper interface {
+	Group(images []Image, similarityThreshold float64) [][]Image
+}